@@ -0,0 +1,129 @@
+package gosocketio
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/gokuai/golang-socketio/protocol"
+)
+
+// ErrNoSuchAck is returned by AckWaiter.WaitAck when asked to wait on
+// an ack id that isn't pending, e.g. because it already timed out.
+var ErrNoSuchAck = errors.New("gosocketio: no pending ack for this id")
+
+/**
+AckWaiter tracks the ack ids a single connection is waiting on and
+delivers each pending ack's raw reply payload once it arrives. One
+AckWaiter is meant to be owned per connection: ack ids are only unique
+within a connection, so Channels that support Emit-with-ack expose
+their own AckWaiter through AckChannel.
+*/
+type AckWaiter struct {
+	mu      sync.Mutex
+	nextID  int
+	pending map[int]chan []byte
+}
+
+func NewAckWaiter() *AckWaiter {
+	return &AckWaiter{pending: make(map[int]chan []byte)}
+}
+
+// AckChannel is implemented by Channels that support Emit-with-ack.
+// Server and Client resolve pending acks against it when routing an
+// AckResponse or BinaryAck Message.
+type AckChannel interface {
+	Channel
+	Acks() *AckWaiter
+}
+
+// Emit sends event/args through channel as an ack-request and blocks
+// until the peer's ack arrives, ctx is done, or sending fails. If
+// reply is non-nil, the ack payload is decoded into it with
+// protocol.Bind.
+func (a *AckWaiter) Emit(ctx context.Context, channel Channel, namespace, event, args string, reply interface{}) error {
+	id := a.register()
+
+	msg := &protocol.Message{
+		Type:      protocol.MessageTypeAckRequest,
+		Namespace: namespace,
+		AckId:     id,
+		Method:    event,
+		Args:      args,
+	}
+
+	if err := channel.Send(msg); err != nil {
+		a.forget(id)
+		return err
+	}
+
+	data, err := a.WaitAck(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if reply == nil {
+		return nil
+	}
+
+	return protocol.Bind(&protocol.Message{Args: string(data)}, reply, nil)
+}
+
+func (a *AckWaiter) register() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.nextID++
+	id := a.nextID
+	a.pending[id] = make(chan []byte, 1)
+
+	return id
+}
+
+func (a *AckWaiter) forget(ackId int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.pending, ackId)
+}
+
+// Resolve delivers data to the waiter registered for ackId, if one is
+// still pending. Server/Client call this once an AckResponse or
+// BinaryAck Message has been decoded for ackId. The reply is held
+// until WaitAck collects it; further replies for the same ackId are
+// dropped.
+func (a *AckWaiter) Resolve(ackId int, data []byte) {
+	a.mu.Lock()
+	ch, ok := a.pending[ackId]
+	a.mu.Unlock()
+
+	if ok {
+		select {
+		case ch <- data:
+		default:
+		}
+	}
+}
+
+// WaitAck blocks until ackId's reply arrives or ctx is done, whichever
+// happens first. On cancellation or timeout the pending entry is
+// removed so long-lived connections do not leak entries for peers
+// that never respond.
+func (a *AckWaiter) WaitAck(ctx context.Context, ackId int) ([]byte, error) {
+	a.mu.Lock()
+	ch, ok := a.pending[ackId]
+	a.mu.Unlock()
+
+	if !ok {
+		return nil, ErrNoSuchAck
+	}
+
+	select {
+	case data := <-ch:
+		a.forget(ackId)
+		return data, nil
+	case <-ctx.Done():
+		a.forget(ackId)
+		return nil, ctx.Err()
+	}
+}
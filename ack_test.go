@@ -0,0 +1,91 @@
+package gosocketio
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gokuai/golang-socketio/protocol"
+)
+
+type recordingChannel struct {
+	sent []*protocol.Message
+}
+
+func (c *recordingChannel) Send(msg *protocol.Message) error {
+	c.sent = append(c.sent, msg)
+	return nil
+}
+
+func TestAckWaiterEmitSingleValueReply(t *testing.T) {
+	waiter := NewAckWaiter()
+	channel := &recordingChannel{}
+
+	done := make(chan error, 1)
+	var reply struct {
+		Ok bool `json:"ok"`
+	}
+	go func() {
+		done <- waiter.Emit(context.Background(), channel, "/", "upload", "1", &reply)
+	}()
+
+	waitForSend(t, channel)
+	waiter.Resolve(channel.sent[0].AckId, []byte(`{"ok":true}`))
+
+	if err := <-done; err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+	if !reply.Ok {
+		t.Fatalf("reply.Ok = false, want true")
+	}
+}
+
+func TestAckWaiterEmitMultiValueReply(t *testing.T) {
+	waiter := NewAckWaiter()
+	channel := &recordingChannel{}
+
+	done := make(chan error, 1)
+	var reply []interface{}
+	go func() {
+		done <- waiter.Emit(context.Background(), channel, "/", "upload", "1", &reply)
+	}()
+
+	waitForSend(t, channel)
+	waiter.Resolve(channel.sent[0].AckId, []byte(`null,42`))
+
+	if err := <-done; err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+	if len(reply) != 2 || reply[1] != float64(42) {
+		t.Fatalf("reply = %v, want [nil 42]", reply)
+	}
+}
+
+func TestAckWaiterEmitContextCancelled(t *testing.T) {
+	waiter := NewAckWaiter()
+	channel := &recordingChannel{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := waiter.Emit(ctx, channel, "/", "upload", "1", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+
+	if _, err := waiter.WaitAck(context.Background(), channel.sent[0].AckId); !errors.Is(err, ErrNoSuchAck) {
+		t.Fatalf("WaitAck after cancellation = %v, want ErrNoSuchAck", err)
+	}
+}
+
+func waitForSend(t *testing.T, channel *recordingChannel) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for len(channel.sent) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for channel.Send")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,67 @@
+package gosocketio
+
+import (
+	"testing"
+
+	"github.com/gokuai/golang-socketio/protocol"
+)
+
+func TestRouteBuffersIncompleteBinaryEvent(t *testing.T) {
+	r := &namespaceRegistry{version: protocol.DefaultProtocolVersion}
+	channel := &recordingChannel{}
+
+	called := false
+	r.Of(DefaultNamespace).On("upload", func(Channel, *protocol.Message) error {
+		called = true
+		return nil
+	})
+
+	msg := &protocol.Message{Type: protocol.MessageTypeBinaryEvent, Method: "upload", NumAttachments: 1}
+	if err := r.route(channel, msg); err != nil {
+		t.Fatalf("route: %v", err)
+	}
+	if called {
+		t.Fatalf("handler ran before the attachment arrived")
+	}
+
+	if err := r.HandleAttachment(channel, []byte("payload")); err != nil {
+		t.Fatalf("HandleAttachment: %v", err)
+	}
+	if !called {
+		t.Fatalf("handler did not run once the attachment arrived")
+	}
+}
+
+func TestHandleAttachmentWithoutPendingMessage(t *testing.T) {
+	r := &namespaceRegistry{version: protocol.DefaultProtocolVersion}
+	channel := &recordingChannel{}
+
+	if err := r.HandleAttachment(channel, []byte("stray")); err != ErrNoPendingAttachments {
+		t.Fatalf("err = %v, want ErrNoPendingAttachments", err)
+	}
+}
+
+func TestServerHandleFrameBuffersBinaryEventUntilAttachmentArrives(t *testing.T) {
+	s := NewServer()
+	channel := &recordingChannel{}
+
+	called := false
+	s.Of(DefaultNamespace).On("upload", func(Channel, *protocol.Message) error {
+		called = true
+		return nil
+	})
+
+	if err := s.HandleFrame(channel, `451-["upload",{"_placeholder":true,"num":0}]`); err != nil {
+		t.Fatalf("HandleFrame: %v", err)
+	}
+	if called {
+		t.Fatalf("handler ran before the attachment arrived")
+	}
+
+	if err := s.HandleAttachment(channel, []byte("data")); err != nil {
+		t.Fatalf("HandleAttachment: %v", err)
+	}
+	if !called {
+		t.Fatalf("handler did not run once the attachment arrived")
+	}
+}
@@ -0,0 +1,62 @@
+package gosocketio
+
+import "github.com/gokuai/golang-socketio/protocol"
+
+/**
+Client is the entry point for namespace-scoped handler registration on
+the connecting side. Its API mirrors Server so the same handlers and
+middleware can be shared between the two roles.
+*/
+type Client struct {
+	namespaceRegistry
+}
+
+// NewClient creates a Client speaking protocol.DefaultProtocolVersion,
+// with no namespaces registered; the default namespace is created
+// lazily on first Of(DefaultNamespace) or on first routed message.
+func NewClient() *Client {
+	return &Client{namespaceRegistry{version: protocol.DefaultProtocolVersion}}
+}
+
+// NewClientForHandshake creates a Client whose protocol version is
+// negotiated from the `EIO` querystring parameter the server replied
+// with, so it can speak either the legacy v2 framing or modern v3/v4
+// framing back to that server.
+func NewClientForHandshake(rawQuery string) *Client {
+	return &Client{namespaceRegistry{version: protocol.NegotiateVersion(rawQuery)}}
+}
+
+// Of returns the Namespace identified by name, creating it on first
+// use.
+func (c *Client) Of(name string) *Namespace {
+	return c.namespaceRegistry.Of(name)
+}
+
+// OnMessage routes a decoded Message from channel to the namespace it
+// targets. Transports call this once they have a *Message off the
+// wire.
+func (c *Client) OnMessage(channel Channel, msg *protocol.Message) error {
+	return c.route(channel, msg)
+}
+
+// HandleFrame decodes a raw wire frame using this Client's negotiated
+// protocol version and routes the result to the namespace it targets.
+// Transports call this with the text frame read straight off the
+// connection.
+func (c *Client) HandleFrame(channel Channel, data string) error {
+	msg, err := c.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	return c.route(channel, msg)
+}
+
+// HandleAttachment feeds a binary websocket frame that follows a
+// BinaryEvent/BinaryAck text frame into the Message channel is still
+// waiting on attachments for. Transports call this for every binary
+// frame read off the connection until the event it belongs to has been
+// routed.
+func (c *Client) HandleAttachment(channel Channel, data []byte) error {
+	return c.namespaceRegistry.HandleAttachment(channel, data)
+}
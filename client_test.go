@@ -0,0 +1,35 @@
+package gosocketio
+
+import (
+	"testing"
+
+	"github.com/gokuai/golang-socketio/protocol"
+)
+
+func TestNewClientForHandshakeNegotiatesVersion(t *testing.T) {
+	c := NewClientForHandshake("EIO=4")
+	if c.version != protocol.ProtocolVersion4 {
+		t.Fatalf("version = %v, want %v", c.version, protocol.ProtocolVersion4)
+	}
+}
+
+func TestClientHandleFrameKeepsAuthPayloadForV4Peer(t *testing.T) {
+	c := NewClientForHandshake("EIO=4")
+	channel := &recordingChannel{}
+
+	var received *protocol.Message
+	c.Of(DefaultNamespace).On("", func(ch Channel, msg *protocol.Message) error {
+		received = msg
+		return nil
+	})
+
+	if err := c.HandleFrame(channel, `40{"token":"abc"}`); err != nil {
+		t.Fatalf("HandleFrame: %v", err)
+	}
+	if received == nil {
+		t.Fatalf("handler was not dispatched")
+	}
+	if received.Args != `{"token":"abc"}` {
+		t.Fatalf("Args = %q, want %q", received.Args, `{"token":"abc"}`)
+	}
+}
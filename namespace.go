@@ -0,0 +1,154 @@
+package gosocketio
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gokuai/golang-socketio/protocol"
+)
+
+// HandlerFunc handles a single decoded message dispatched to a Channel.
+type HandlerFunc func(channel Channel, msg *protocol.Message) error
+
+// MiddlewareFunc runs before a Namespace dispatches a message to its
+// registered handler. Returning a non-nil error aborts dispatch; the
+// handler for msg.Method is not called.
+type MiddlewareFunc func(channel Channel, msg *protocol.Message) error
+
+// Channel is anything a Namespace can deliver packets to. Transports
+// implement this to plug their connections into the namespace and
+// middleware layer.
+type Channel interface {
+	Send(msg *protocol.Message) error
+}
+
+/**
+Namespace groups event handlers, middleware and joined channels under a
+single socket.io namespace (e.g. "/", "/chat"). Server and Client hand
+out namespaces via their Of method and route decoded messages to the
+one named by Message.Namespace.
+*/
+type Namespace struct {
+	name       string
+	mu         sync.RWMutex
+	handlers   map[string]HandlerFunc
+	middleware []MiddlewareFunc
+	channels   map[Channel]bool
+}
+
+func newNamespace(name string) *Namespace {
+	return &Namespace{
+		name:     name,
+		handlers: make(map[string]HandlerFunc),
+		channels: make(map[Channel]bool),
+	}
+}
+
+// On registers a handler for the named event within this namespace,
+// replacing any handler previously registered for the same event.
+func (n *Namespace) On(event string, handler HandlerFunc) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.handlers[event] = handler
+}
+
+// Use appends a middleware function to the chain run before dispatch.
+func (n *Namespace) Use(mw MiddlewareFunc) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.middleware = append(n.middleware, mw)
+}
+
+// Join marks channel as connected to this namespace, making it
+// reachable from BroadcastTo.
+func (n *Namespace) Join(channel Channel) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.channels[channel] = true
+}
+
+// Leave removes a channel previously added with Join.
+func (n *Namespace) Leave(channel Channel) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	delete(n.channels, channel)
+}
+
+// Dispatch runs the middleware chain and, if none of them error, the
+// handler registered for msg.Method. Server and Client call this once
+// a Message has been decoded and routed to this namespace.
+func (n *Namespace) Dispatch(channel Channel, msg *protocol.Message) error {
+	n.mu.RLock()
+	middleware := n.middleware
+	handler, ok := n.handlers[msg.Method]
+	n.mu.RUnlock()
+
+	for _, mw := range middleware {
+		if err := mw(channel, msg); err != nil {
+			return err
+		}
+	}
+
+	if !ok {
+		return nil
+	}
+
+	return handler(channel, msg)
+}
+
+// Emit sends an event to a single channel, prefixing this namespace on
+// the wire. The default namespace ("/") is never written on the wire,
+// matching how real socket.io peers encode and parse it.
+func (n *Namespace) Emit(channel Channel, event, args string) error {
+	return channel.Send(&protocol.Message{
+		Type:      protocol.MessageTypeEmit,
+		Namespace: n.wireName(),
+		Method:    event,
+		Args:      args,
+	})
+}
+
+// EmitWithAck sends event to channel as an ack-request and blocks for
+// the peer's reply, honoring ctx's deadline/cancellation. The ack
+// payload is decoded into reply if it is non-nil.
+func (n *Namespace) EmitWithAck(ctx context.Context, channel AckChannel, event, args string, reply interface{}) error {
+	return channel.Acks().Emit(ctx, channel, n.wireName(), event, args, reply)
+}
+
+// wireName returns the namespace name to put on the wire, which is
+// empty for the default namespace: real socket.io peers encode the
+// root namespace by omitting it entirely rather than writing "/".
+func (n *Namespace) wireName() string {
+	if n.name == DefaultNamespace {
+		return ""
+	}
+	return n.name
+}
+
+// BroadcastTo sends an event to every channel currently joined to this
+// namespace, prefixing the namespace on the wire. The default namespace
+// ("/") is never written on the wire, matching how real socket.io peers
+// encode and parse it.
+func (n *Namespace) BroadcastTo(event, args string) error {
+	msg := &protocol.Message{
+		Type:      protocol.MessageTypeEmit,
+		Namespace: n.wireName(),
+		Method:    event,
+		Args:      args,
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	for channel := range n.channels {
+		if err := channel.Send(msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
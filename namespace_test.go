@@ -0,0 +1,113 @@
+package gosocketio
+
+import (
+	"testing"
+
+	"github.com/gokuai/golang-socketio/protocol"
+)
+
+func TestNamespaceEmitOmitsDefaultNamespaceOnWire(t *testing.T) {
+	ns := newNamespace(DefaultNamespace)
+	channel := &recordingChannel{}
+
+	if err := ns.Emit(channel, "foo", `1`); err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+
+	text, err := protocol.Encode(channel.sent[0])
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if want := `42["foo",1]`; text != want {
+		t.Fatalf("Encode(Emit) = %q, want %q", text, want)
+	}
+}
+
+func TestNamespaceEmitKeepsNonDefaultNamespaceOnWire(t *testing.T) {
+	ns := newNamespace("/chat")
+	channel := &recordingChannel{}
+
+	if err := ns.Emit(channel, "foo", `1`); err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+
+	text, err := protocol.Encode(channel.sent[0])
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if want := `42/chat,["foo",1]`; text != want {
+		t.Fatalf("Encode(Emit) = %q, want %q", text, want)
+	}
+}
+
+func TestNamespaceBroadcastToOmitsDefaultNamespaceOnWire(t *testing.T) {
+	ns := newNamespace(DefaultNamespace)
+	channel := &recordingChannel{}
+	ns.Join(channel)
+
+	if err := ns.BroadcastTo("foo", `1`); err != nil {
+		t.Fatalf("BroadcastTo: %v", err)
+	}
+
+	text, err := protocol.Encode(channel.sent[0])
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if want := `42["foo",1]`; text != want {
+		t.Fatalf("Encode(BroadcastTo) = %q, want %q", text, want)
+	}
+}
+
+func TestNamespaceDispatchRunsMiddlewareThenHandler(t *testing.T) {
+	ns := newNamespace(DefaultNamespace)
+	channel := &recordingChannel{}
+
+	var order []string
+	ns.Use(func(Channel, *protocol.Message) error {
+		order = append(order, "middleware")
+		return nil
+	})
+	ns.On("foo", func(Channel, *protocol.Message) error {
+		order = append(order, "handler")
+		return nil
+	})
+
+	msg := &protocol.Message{Type: protocol.MessageTypeEmit, Method: "foo"}
+	if err := ns.Dispatch(channel, msg); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+
+	if len(order) != 2 || order[0] != "middleware" || order[1] != "handler" {
+		t.Fatalf("order = %v, want [middleware handler]", order)
+	}
+}
+
+func TestNamespaceDispatchAbortsOnMiddlewareError(t *testing.T) {
+	ns := newNamespace(DefaultNamespace)
+	channel := &recordingChannel{}
+
+	wantErr := errNamespaceTest
+	ns.Use(func(Channel, *protocol.Message) error {
+		return wantErr
+	})
+
+	called := false
+	ns.On("foo", func(Channel, *protocol.Message) error {
+		called = true
+		return nil
+	})
+
+	msg := &protocol.Message{Type: protocol.MessageTypeEmit, Method: "foo"}
+	if err := ns.Dispatch(channel, msg); err != wantErr {
+		t.Fatalf("Dispatch error = %v, want %v", err, wantErr)
+	}
+	if called {
+		t.Fatalf("handler ran despite middleware error")
+	}
+}
+
+var errNamespaceTest = &namespaceTestError{"middleware rejected"}
+
+type namespaceTestError struct{ s string }
+
+func (e *namespaceTestError) Error() string { return e.s }
@@ -0,0 +1,90 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+/**
+PlaceholderArgs marshals args into the comma-separated inner-array form
+Message.Args expects, replacing every []byte element with a
+`{"_placeholder":true,"num":N}` marker (N counting up from 0 in
+encounter order) and returning the replaced byte slices as the ordered
+attachment list that must follow the text frame on the wire. args holds
+only the event's arguments, not the event name itself: for a
+BinaryEvent, Encode writes Message.Method as the array's first element,
+exactly as it does for a plain Emit, so set Method separately; a
+BinaryAck is written with no method. Pair it with EncodeBinary
+to build a BinaryEvent/BinaryAck Message whose Args and Attachments are
+actually linked:
+
+	args, attachments, err := PlaceholderArgs([]interface{}{data})
+	msg := &Message{Type: MessageTypeBinaryEvent, Method: "upload", Args: args, Attachments: attachments}
+	text, frames, err := EncodeBinary(msg)
+*/
+func PlaceholderArgs(args []interface{}) (string, [][]byte, error) {
+	var attachments [][]byte
+	replaced := make([]interface{}, len(args))
+
+	for i, v := range args {
+		b, ok := v.([]byte)
+		if !ok {
+			replaced[i] = v
+			continue
+		}
+
+		replaced[i] = map[string]interface{}{
+			"_placeholder": true,
+			"num":          len(attachments),
+		}
+		attachments = append(attachments, b)
+	}
+
+	data, err := json.Marshal(replaced)
+	if err != nil {
+		return "", nil, err
+	}
+
+	inner := strings.TrimSuffix(strings.TrimPrefix(string(data), "["), "]")
+
+	return inner, attachments, nil
+}
+
+/**
+ResolveAttachments parses msg.Args and replaces every
+`{"_placeholder":true,"num":N}` marker with the corresponding []byte
+from msg.Attachments, returning the fully reassembled argument list.
+It is the inverse of PlaceholderArgs and is only valid once
+msg.Ready() reports true, i.e. every attachment frame has arrived.
+*/
+func ResolveAttachments(msg *Message) ([]interface{}, error) {
+	if !msg.Ready() {
+		return nil, fmt.Errorf("protocol: message has unresolved attachments (%d/%d)", len(msg.Attachments), msg.NumAttachments)
+	}
+
+	var args []interface{}
+	if err := json.Unmarshal([]byte("["+msg.Args+"]"), &args); err != nil {
+		return nil, err
+	}
+
+	for i, v := range args {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if placeholder, _ := obj["_placeholder"].(bool); !placeholder {
+			continue
+		}
+
+		num, ok := obj["num"].(float64)
+		if !ok || int(num) < 0 || int(num) >= len(msg.Attachments) {
+			return nil, fmt.Errorf("protocol: attachment placeholder num %v out of range", obj["num"])
+		}
+
+		args[i] = msg.Attachments[int(num)]
+	}
+
+	return args, nil
+}
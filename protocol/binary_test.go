@@ -0,0 +1,70 @@
+package protocol
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlaceholderArgsRoundTrip(t *testing.T) {
+	args, attachments, err := PlaceholderArgs([]interface{}{[]byte("hello")})
+	if err != nil {
+		t.Fatalf("PlaceholderArgs: %v", err)
+	}
+	if len(attachments) != 1 || string(attachments[0]) != "hello" {
+		t.Fatalf("attachments = %v, want [hello]", attachments)
+	}
+
+	msg := &Message{
+		Type:           MessageTypeBinaryEvent,
+		Method:         "upload",
+		Args:           args,
+		NumAttachments: len(attachments),
+		Attachments:    attachments,
+	}
+
+	text, frames, err := EncodeBinary(msg)
+	if err != nil {
+		t.Fatalf("EncodeBinary: %v", err)
+	}
+	if want := `451-["upload",{"_placeholder":true,"num":0}]`; text != want {
+		t.Fatalf("EncodeBinary text = %q, want %q", text, want)
+	}
+	if len(frames) != 1 || string(frames[0]) != "hello" {
+		t.Fatalf("frames = %v, want [hello]", frames)
+	}
+
+	decoded, err := Decode(text)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if decoded.Method != "upload" {
+		t.Fatalf("decoded.Method = %q, want %q", decoded.Method, "upload")
+	}
+	if decoded.Ready() {
+		t.Fatalf("decoded.Ready() = true before any attachment arrived")
+	}
+
+	for _, frame := range frames {
+		decoded.AddAttachment(frame)
+	}
+	if !decoded.Ready() {
+		t.Fatalf("decoded.Ready() = false after every attachment arrived")
+	}
+
+	resolved, err := ResolveAttachments(decoded)
+	if err != nil {
+		t.Fatalf("ResolveAttachments: %v", err)
+	}
+	want := []interface{}{[]byte("hello")}
+	if !reflect.DeepEqual(resolved, want) {
+		t.Fatalf("resolved = %v, want %v", resolved, want)
+	}
+}
+
+func TestResolveAttachmentsBeforeReady(t *testing.T) {
+	msg := &Message{Type: MessageTypeBinaryEvent, NumAttachments: 1}
+
+	if _, err := ResolveAttachments(msg); err == nil {
+		t.Fatalf("ResolveAttachments succeeded before every attachment arrived")
+	}
+}
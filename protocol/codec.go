@@ -0,0 +1,66 @@
+package protocol
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+/**
+Codec marshals and unmarshals the JSON payloads carried by emit and ack
+packets. DefaultCodec is used wherever a nil Codec is passed in; supply
+a different implementation (jsoniter, sonic, msgpack, ...) to change
+how Args/ack replies are encoded without touching the framing in
+Encode/Decode.
+*/
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// DefaultCodec is the Codec used by Bind when none is specified.
+var DefaultCodec Codec = jsonCodec{}
+
+// Bind decodes msg.Args into v using codec, or DefaultCodec if codec
+// is nil. Args holds the comma-separated argument list of an emit or
+// ack packet without its enclosing brackets. When v points to a slice
+// or array, the caller wants every argument, so Args is wrapped back
+// into a JSON array before decoding; otherwise v wants just the single
+// (and usual) argument, and Args is already that bare JSON value.
+func Bind(msg *Message, v interface{}, codec Codec) error {
+	if codec == nil {
+		codec = DefaultCodec
+	}
+
+	if wantsArgList(v) {
+		return codec.Unmarshal([]byte("["+msg.Args+"]"), v)
+	}
+
+	return codec.Unmarshal([]byte(msg.Args), v)
+}
+
+// wantsArgList reports whether v is a pointer to a slice or array,
+// i.e. whether it expects the full argument list rather than a single
+// decoded value.
+func wantsArgList(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return false
+	}
+
+	switch rv.Elem().Kind() {
+	case reflect.Slice, reflect.Array:
+		return true
+	default:
+		return false
+	}
+}
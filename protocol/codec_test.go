@@ -0,0 +1,70 @@
+package protocol
+
+import "testing"
+
+func TestBindSingleValue(t *testing.T) {
+	msg := &Message{Args: `{"ok":true}`}
+
+	var reply struct {
+		Ok bool `json:"ok"`
+	}
+	if err := Bind(msg, &reply, nil); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if !reply.Ok {
+		t.Fatalf("reply.Ok = false, want true")
+	}
+}
+
+func TestBindArgList(t *testing.T) {
+	msg := &Message{Args: `null,42`}
+
+	var reply []interface{}
+	if err := Bind(msg, &reply, nil); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if len(reply) != 2 {
+		t.Fatalf("len(reply) = %d, want 2", len(reply))
+	}
+	if reply[0] != nil {
+		t.Fatalf("reply[0] = %v, want nil", reply[0])
+	}
+	if reply[1] != float64(42) {
+		t.Fatalf("reply[1] = %v, want 42", reply[1])
+	}
+}
+
+func TestBindCustomCodec(t *testing.T) {
+	msg := &Message{Args: `"pong"`}
+
+	calls := 0
+	codec := funcCodec{
+		unmarshal: func(data []byte, v interface{}) error {
+			calls++
+			return jsonCodec{}.Unmarshal(data, v)
+		},
+	}
+
+	var reply string
+	if err := Bind(msg, &reply, codec); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if reply != "pong" {
+		t.Fatalf("reply = %q, want %q", reply, "pong")
+	}
+	if calls != 1 {
+		t.Fatalf("codec.Unmarshal called %d times, want 1", calls)
+	}
+}
+
+type funcCodec struct {
+	unmarshal func(data []byte, v interface{}) error
+}
+
+func (funcCodec) Marshal(v interface{}) ([]byte, error) {
+	return jsonCodec{}.Marshal(v)
+}
+
+func (c funcCodec) Unmarshal(data []byte, v interface{}) error {
+	return c.unmarshal(data, v)
+}
@@ -0,0 +1,52 @@
+package protocol
+
+import "testing"
+
+func FuzzDecode(f *testing.F) {
+	seeds := []string{
+		"",
+		"0",
+		"1",
+		"2",
+		"3",
+		"4",
+		"40",
+		"41",
+		`40{"sid":"abc"}`,
+		`41/chat`,
+		`42["foo"]`,
+		`42["foo","bar",1]`,
+		`42/chat,["foo",1]`,
+		`43[1]`,
+		`431["pong"]`,
+		`451-["upload",{"_placeholder":true,"num":0}]`,
+		`460-1[{"_placeholder":true,"num":0}]`,
+		`4`,
+		`45`,
+		`45-`,
+		`43`,
+		`42[`,
+		`42]`,
+		`42["a`,
+		`42/ns`,
+		`42/ns,`,
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, data string) {
+		msg, err := Decode(data)
+		if err != nil {
+			return
+		}
+		if msg == nil {
+			t.Fatalf("Decode(%q) returned nil message with nil error", data)
+		}
+		if msg.Args != "" {
+			if len(msg.Args) > len(data) {
+				t.Fatalf("Decode(%q) produced Args longer than input: %q", data, msg.Args)
+			}
+		}
+	})
+}
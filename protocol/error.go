@@ -0,0 +1,30 @@
+package protocol
+
+import "fmt"
+
+/**
+ParseError describes a Decode failure with enough context to log or to
+report back to a misbehaving peer, instead of the bare ErrorWrongPacket
+sentinel. Offset is the byte offset into Frame where decoding gave up;
+Reason is a short human-readable explanation. ParseError unwraps to
+ErrorWrongMessageType or ErrorWrongPacket, so existing `errors.Is`
+checks against those sentinels keep working.
+*/
+type ParseError struct {
+	Offset int
+	Reason string
+	Frame  string
+	cause  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("protocol: %s at offset %d in %q", e.Reason, e.Offset, e.Frame)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.cause
+}
+
+func newParseError(frame string, offset int, reason string, cause error) *ParseError {
+	return &ParseError{Offset: offset, Reason: reason, Frame: frame, cause: cause}
+}
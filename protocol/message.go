@@ -0,0 +1,56 @@
+package protocol
+
+const (
+	MessageTypeOpen = iota
+	MessageTypeClose
+	MessageTypePing
+	MessageTypePong
+	MessageTypeEmpty
+	MessageTypeEmit
+	MessageTypeAckRequest
+	MessageTypeAckResponse
+	MessageTypeBinaryEvent
+	MessageTypeBinaryAck
+	MessageTypeDisconnect
+)
+
+/**
+Message represents a single engine.io/socket.io packet, decoded from or
+ready to be encoded to the wire text representation.
+
+Attachments holds the raw binary frames belonging to a BinaryEvent or
+BinaryAck packet, in the order they were received. NumAttachments is the
+count advertised by the `<num>-` prefix on the wire; once
+len(Attachments) == NumAttachments the message is complete and may be
+dispatched.
+*/
+type Message struct {
+	Type           int
+	AckId          int
+	Method         string
+	Args           string
+	Namespace      string
+	Source         string
+	NumAttachments int
+	Attachments    [][]byte
+}
+
+// IsBinary reports whether this message carries binary attachments.
+func (m *Message) IsBinary() bool {
+	return m.Type == MessageTypeBinaryEvent || m.Type == MessageTypeBinaryAck
+}
+
+// Ready reports whether all attachments declared by NumAttachments have
+// been received and the message can be safely dispatched upstream.
+func (m *Message) Ready() bool {
+	if !m.IsBinary() {
+		return true
+	}
+	return len(m.Attachments) >= m.NumAttachments
+}
+
+// AddAttachment appends a binary frame received after this message's
+// text frame, in wire order.
+func (m *Message) AddAttachment(data []byte) {
+	m.Attachments = append(m.Attachments, data)
+}
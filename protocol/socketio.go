@@ -8,15 +8,18 @@ import (
 )
 
 const (
-	open          = "0"
-	msg           = "4"
-	emptyMessage  = "40"
-	commonMessage = "42"
-	ackMessage    = "43"
+	open               = "0"
+	msg                = "4"
+	emptyMessage       = "40"
+	disconnectMessage  = "41"
+	commonMessage      = "42"
+	ackMessage         = "43"
+	binaryEventMessage = "45"
+	binaryAckMessage   = "46"
 
 	CloseMessage = "1"
-	PingMessage = "2"
-	PongMessage = "3"
+	PingMessage  = "2"
+	PongMessage  = "3"
 )
 
 var (
@@ -36,10 +39,16 @@ func typeToText(msgType int) (string, error) {
 		return PongMessage, nil
 	case MessageTypeEmpty:
 		return emptyMessage, nil
+	case MessageTypeDisconnect:
+		return disconnectMessage, nil
 	case MessageTypeEmit, MessageTypeAckRequest:
 		return commonMessage, nil
 	case MessageTypeAckResponse:
 		return ackMessage, nil
+	case MessageTypeBinaryEvent:
+		return binaryEventMessage, nil
+	case MessageTypeBinaryAck:
+		return binaryAckMessage, nil
 	}
 	return "", ErrorWrongMessageType
 }
@@ -50,18 +59,34 @@ func Encode(msg *Message) (string, error) {
 		return "", err
 	}
 
+	if msg.IsBinary() {
+		result += strconv.Itoa(len(msg.Attachments)) + "-"
+	}
+
 	comma := false
 	if msg.Namespace != "" {
 		result += msg.Namespace
 		comma = true
 	}
 
-	if msg.Type == MessageTypeEmpty || msg.Type == MessageTypePing ||
-		msg.Type == MessageTypePong {
+	if msg.Type == MessageTypePing || msg.Type == MessageTypePong {
 		return result, nil
 	}
 
-	if msg.Type == MessageTypeAckRequest || msg.Type == MessageTypeAckResponse {
+	if msg.Type == MessageTypeEmpty || msg.Type == MessageTypeDisconnect {
+		if msg.Args == "" {
+			return result, nil
+		}
+		if comma {
+			result += ","
+			comma = false
+		}
+		return result + msg.Args, nil
+	}
+
+	if msg.Type == MessageTypeAckRequest || msg.Type == MessageTypeAckResponse ||
+		msg.Type == MessageTypeBinaryAck ||
+		(msg.Type == MessageTypeBinaryEvent && msg.AckId != 0) {
 		if comma {
 			result += ","
 			comma = false
@@ -77,7 +102,7 @@ func Encode(msg *Message) (string, error) {
 		return result + msg.Args, nil
 	}
 
-	if msg.Type == MessageTypeAckResponse {
+	if msg.Type == MessageTypeAckResponse || msg.Type == MessageTypeBinaryAck {
 		if comma {
 			result += ","
 			comma = false
@@ -98,6 +123,20 @@ func Encode(msg *Message) (string, error) {
 	return result + "[" + string(jsonMethod) + "," + msg.Args + "]", nil
 }
 
+// EncodeBinary encodes a binary event/ack Message into its text frame
+// plus the ordered binary frames that must follow it on the wire. Use
+// PlaceholderArgs to build msg.Args/msg.Attachments from a mixed
+// []interface{} argument list instead of placing
+// `{"_placeholder":true,"num":N}` markers by hand.
+func EncodeBinary(msg *Message) (string, [][]byte, error) {
+	text, err := Encode(msg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return text, msg.Attachments, nil
+}
+
 func MustEncode(msg *Message) string {
 	result, err := Encode(msg)
 	if err != nil {
@@ -134,18 +173,45 @@ func getMessageType(data string) (t int, restText string, err error) {
 		case emptyMessage:
 			t = MessageTypeEmpty
 			return
+		case disconnectMessage:
+			t = MessageTypeDisconnect
+			return
 		case commonMessage:
 			t = MessageTypeAckRequest
 			return
 		case ackMessage:
 			t = MessageTypeAckResponse
 			return
+		case binaryEventMessage:
+			t = MessageTypeBinaryEvent
+			return
+		case binaryAckMessage:
+			t = MessageTypeBinaryAck
+			return
 		}
 	}
 	err = ErrorWrongMessageType
 	return
 }
 
+/**
+Get the `<num>-` attachment count prefix of a binary packet; a packet
+without one is rejected with ErrorWrongPacket
+*/
+func getAttachmentCount(text string) (count int, restText string, err error) {
+	pos := strings.IndexByte(text, '-')
+	if pos == -1 {
+		return 0, "", ErrorWrongPacket
+	}
+
+	count, err = strconv.Atoi(text[0:pos])
+	if err != nil {
+		return 0, "", ErrorWrongPacket
+	}
+
+	return count, text[pos+1:], nil
+}
+
 func getNamespace(text string) (namespace string, restText string) {
 	if len(text) == 0 {
 		return
@@ -184,7 +250,7 @@ func getAck(text string) (ackId int, restText string, err error) {
 
 	ack, err := strconv.Atoi(text[0:pos])
 	if err != nil {
-		return 0, "", err
+		return 0, "", ErrorWrongPacket
 	}
 
 	return ack, text[pos:], nil
@@ -194,35 +260,33 @@ func getAck(text string) (ackId int, restText string, err error) {
 Get message method of current packet, if present
 */
 func getMethod(text string) (method, restText string, err error) {
-	var start, end, rest, countQuote int
-
-	for i, c := range text {
-		if c == '"' {
-			switch countQuote {
-			case 0:
-				start = i + 1
-			case 1:
-				end = i
-				rest = i + 1
-			default:
-				return "", "", ErrorWrongPacket
-			}
-			countQuote++
-		}
-		if c == ',' {
-			if countQuote < 2 {
-				continue
-			}
-			rest = i + 1
-			break
-		}
+	if len(text) == 0 || text[len(text)-1] != ']' {
+		return "", "", ErrorWrongPacket
+	}
+
+	dec := json.NewDecoder(strings.NewReader(text))
+
+	tok, tokErr := dec.Token()
+	if tokErr != nil {
+		return "", "", ErrorWrongPacket
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return "", "", ErrorWrongPacket
+	}
+
+	if decErr := dec.Decode(&method); decErr != nil {
+		return "", "", ErrorWrongPacket
 	}
 
-	if (end < start) || (rest >= len(text)) {
+	offset := int(dec.InputOffset())
+	if offset > len(text)-1 {
 		return "", "", ErrorWrongPacket
 	}
 
-	return text[start:end], text[rest : len(text)-1], nil
+	rest := text[offset : len(text)-1]
+	rest = strings.TrimPrefix(rest, ",")
+
+	return method, rest, nil
 }
 
 func Decode(data string) (*Message, error) {
@@ -230,10 +294,22 @@ func Decode(data string) (*Message, error) {
 	msg := &Message{}
 	msg.Source = data
 
+	offset := func(rest string) int {
+		return len(data) - len(rest)
+	}
+
 	var rest string
 	msg.Type, rest, err = getMessageType(data)
 	if err != nil {
-		return nil, err
+		return nil, newParseError(data, 0, "unrecognised message type", err)
+	}
+
+	if msg.Type == MessageTypeBinaryEvent || msg.Type == MessageTypeBinaryAck {
+		before := rest
+		msg.NumAttachments, rest, err = getAttachmentCount(rest)
+		if err != nil {
+			return nil, newParseError(data, offset(before), "missing attachment count", err)
+		}
 	}
 
 	msg.Namespace, rest = getNamespace(rest)
@@ -244,28 +320,42 @@ func Decode(data string) (*Message, error) {
 	}
 
 	if msg.Type == MessageTypeClose || msg.Type == MessageTypePing ||
-		msg.Type == MessageTypePong || msg.Type == MessageTypeEmpty {
+		msg.Type == MessageTypePong {
 		return msg, nil
 	}
 
+	if msg.Type == MessageTypeEmpty || msg.Type == MessageTypeDisconnect {
+		if rest != "" {
+			msg.Args = rest
+		}
+		return msg, nil
+	}
+
+	preAck := rest
 	ack, rest, err := getAck(rest)
 	msg.AckId = ack
-	if msg.Type == MessageTypeAckResponse {
+	if msg.Type == MessageTypeAckResponse || msg.Type == MessageTypeBinaryAck {
 		if err != nil {
-			return nil, err
+			return nil, newParseError(data, offset(preAck), "missing ack payload", err)
+		}
+		if len(rest) < 2 || rest[len(rest)-1] != ']' {
+			return nil, newParseError(data, offset(rest), "malformed ack payload", ErrorWrongPacket)
 		}
 		msg.Args = rest[1 : len(rest)-1]
 		return msg, nil
 	}
 
 	if err != nil {
-		msg.Type = MessageTypeEmit
-		rest = data[2:]
+		if msg.Type != MessageTypeBinaryEvent {
+			msg.Type = MessageTypeEmit
+		}
+		rest = preAck
 	}
 
+	before := rest
 	msg.Method, msg.Args, err = getMethod(rest)
 	if err != nil {
-		return nil, err
+		return nil, newParseError(data, offset(before), "malformed method/args", err)
 	}
 
 	return msg, nil
@@ -0,0 +1,69 @@
+package protocol
+
+import "testing"
+
+func TestGetMethodHandlesEscapedQuotesAndCommasInArgs(t *testing.T) {
+	method, rest, err := getMethod(`["say","he said \"hi, there\"",42]`)
+	if err != nil {
+		t.Fatalf("getMethod: %v", err)
+	}
+	if method != "say" {
+		t.Fatalf("method = %q, want %q", method, "say")
+	}
+	if want := `"he said \"hi, there\"",42`; rest != want {
+		t.Fatalf("rest = %q, want %q", rest, want)
+	}
+}
+
+func TestGetMethodRejectsMalformedInput(t *testing.T) {
+	cases := []string{"", "not json", `["unterminated`, `{"not":"an array"}`}
+
+	for _, c := range cases {
+		if _, _, err := getMethod(c); err == nil {
+			t.Errorf("getMethod(%q) succeeded, want error", c)
+		}
+	}
+}
+
+func TestDecodeEmitWithMoreThanTwoArgs(t *testing.T) {
+	msg, err := Decode(`42["foo","bar",1,true]`)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if msg.Method != "foo" {
+		t.Fatalf("Method = %q, want %q", msg.Method, "foo")
+	}
+	if want := `"bar",1,true`; msg.Args != want {
+		t.Fatalf("Args = %q, want %q", msg.Args, want)
+	}
+}
+
+func TestDecodeEncodeDefaultCodecRoundTrip(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+
+	data, err := DefaultCodec.Marshal(&payload{Name: "foo"})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	msg := &Message{Type: MessageTypeEmit, Method: "greet", Args: string(data)}
+	text, err := Encode(msg)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	decoded, err := Decode(text)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	var got payload
+	if err := Bind(decoded, &got, nil); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if got.Name != "foo" {
+		t.Fatalf("got.Name = %q, want %q", got.Name, "foo")
+	}
+}
@@ -0,0 +1,108 @@
+package protocol
+
+import (
+	"encoding/json"
+	"net/url"
+)
+
+type ProtocolVersion int
+
+const (
+	ProtocolVersion2 ProtocolVersion = 2
+	ProtocolVersion3 ProtocolVersion = 3
+	ProtocolVersion4 ProtocolVersion = 4
+
+	DefaultProtocolVersion = ProtocolVersion4
+)
+
+/**
+NegotiateVersion inspects the `EIO` querystring parameter sent by the
+peer at handshake time and returns the Engine.IO protocol version to
+speak for the rest of the connection. Peers that omit EIO, or send a
+value we don't recognise, are assumed to be legacy v2 clients.
+*/
+func NegotiateVersion(rawQuery string) ProtocolVersion {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return ProtocolVersion2
+	}
+
+	switch values.Get("EIO") {
+	case "4":
+		return ProtocolVersion4
+	case "3":
+		return ProtocolVersion3
+	default:
+		return ProtocolVersion2
+	}
+}
+
+/**
+Handshake is the JSON payload carried by the Engine.IO OPEN packet,
+advertising the session id and transport parameters negotiated with
+the peer.
+*/
+type Handshake struct {
+	Sid          string   `json:"sid"`
+	Upgrades     []string `json:"upgrades"`
+	PingInterval int      `json:"pingInterval"`
+	PingTimeout  int      `json:"pingTimeout"`
+	MaxPayload   int      `json:"maxPayload,omitempty"`
+}
+
+func EncodeHandshake(h *Handshake) (string, error) {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func DecodeHandshake(data string) (*Handshake, error) {
+	h := &Handshake{}
+	if err := json.Unmarshal([]byte(data), h); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+/**
+EncodeVersion encodes msg the way a peer speaking the given protocol
+version expects. socket.io only added CONNECT/DISCONNECT auth payloads
+in v3, so for ProtocolVersion2 peers any Args on those packet types are
+dropped rather than sent to a peer that doesn't know to expect them.
+*/
+func EncodeVersion(msg *Message, version ProtocolVersion) (string, error) {
+	if version < ProtocolVersion3 && isAuthCarrier(msg.Type) && msg.Args != "" {
+		stripped := *msg
+		stripped.Args = ""
+		return Encode(&stripped)
+	}
+
+	return Encode(msg)
+}
+
+/**
+DecodeVersion decodes data as sent by a peer speaking the given
+protocol version. For ProtocolVersion2 peers, any payload on a
+CONNECT/DISCONNECT packet is dropped instead of being handed upstream
+as if it were v3+ auth data.
+*/
+func DecodeVersion(data string, version ProtocolVersion) (*Message, error) {
+	msg, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if version < ProtocolVersion3 && isAuthCarrier(msg.Type) {
+		msg.Args = ""
+	}
+
+	return msg, nil
+}
+
+func isAuthCarrier(msgType int) bool {
+	return msgType == MessageTypeEmpty || msgType == MessageTypeDisconnect
+}
@@ -0,0 +1,84 @@
+package protocol
+
+import "testing"
+
+func TestNegotiateVersion(t *testing.T) {
+	cases := []struct {
+		query string
+		want  ProtocolVersion
+	}{
+		{"", ProtocolVersion2},
+		{"EIO=2", ProtocolVersion2},
+		{"EIO=3", ProtocolVersion3},
+		{"EIO=4", ProtocolVersion4},
+		{"EIO=9", ProtocolVersion2},
+		{"transport=polling&EIO=4", ProtocolVersion4},
+	}
+
+	for _, c := range cases {
+		if got := NegotiateVersion(c.query); got != c.want {
+			t.Errorf("NegotiateVersion(%q) = %v, want %v", c.query, got, c.want)
+		}
+	}
+}
+
+func TestEncodeVersionStripsAuthPayloadBelowV3(t *testing.T) {
+	msg := &Message{Type: MessageTypeEmpty, Args: `{"token":"abc"}`}
+
+	text, err := EncodeVersion(msg, ProtocolVersion2)
+	if err != nil {
+		t.Fatalf("EncodeVersion: %v", err)
+	}
+	if want := "40"; text != want {
+		t.Fatalf("EncodeVersion(v2) = %q, want %q", text, want)
+	}
+
+	text, err = EncodeVersion(msg, ProtocolVersion4)
+	if err != nil {
+		t.Fatalf("EncodeVersion: %v", err)
+	}
+	if want := `40{"token":"abc"}`; text != want {
+		t.Fatalf("EncodeVersion(v4) = %q, want %q", text, want)
+	}
+
+	if msg.Args == "" {
+		t.Fatalf("EncodeVersion mutated the caller's Message")
+	}
+}
+
+func TestDecodeVersionDropsAuthPayloadBelowV3(t *testing.T) {
+	data := `40{"token":"abc"}`
+
+	msg, err := DecodeVersion(data, ProtocolVersion2)
+	if err != nil {
+		t.Fatalf("DecodeVersion: %v", err)
+	}
+	if msg.Args != "" {
+		t.Fatalf("DecodeVersion(v2).Args = %q, want empty", msg.Args)
+	}
+
+	msg, err = DecodeVersion(data, ProtocolVersion4)
+	if err != nil {
+		t.Fatalf("DecodeVersion: %v", err)
+	}
+	if msg.Args != `{"token":"abc"}` {
+		t.Fatalf("DecodeVersion(v4).Args = %q, want %q", msg.Args, `{"token":"abc"}`)
+	}
+}
+
+func TestHandshakeRoundTrip(t *testing.T) {
+	h := &Handshake{Sid: "abc123", Upgrades: []string{"websocket"}, PingInterval: 25000, PingTimeout: 5000}
+
+	text, err := EncodeHandshake(h)
+	if err != nil {
+		t.Fatalf("EncodeHandshake: %v", err)
+	}
+
+	decoded, err := DecodeHandshake(text)
+	if err != nil {
+		t.Fatalf("DecodeHandshake: %v", err)
+	}
+	if decoded.Sid != h.Sid || decoded.PingInterval != h.PingInterval {
+		t.Fatalf("decoded = %+v, want %+v", decoded, h)
+	}
+}
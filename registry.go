@@ -0,0 +1,130 @@
+package gosocketio
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/gokuai/golang-socketio/protocol"
+)
+
+// DefaultNamespace is used for messages that carry no explicit
+// namespace on the wire, matching socket.io's "/" root namespace.
+const DefaultNamespace = "/"
+
+// ErrNoPendingAttachments is returned by HandleAttachment when no
+// BinaryEvent/BinaryAck message on channel is currently waiting on
+// attachment frames, e.g. because it was already completed or no
+// binary text frame preceded this one.
+var ErrNoPendingAttachments = errors.New("gosocketio: no message pending attachments for this channel")
+
+// namespaceRegistry is embedded by Server and Client to provide the
+// Of(namespace) accessor, the routing of decoded messages to the
+// namespace they target, and version-aware framing for the connection.
+type namespaceRegistry struct {
+	mu         sync.Mutex
+	namespaces map[string]*Namespace
+	version    protocol.ProtocolVersion
+	pending    map[Channel]*protocol.Message
+}
+
+// Decode decodes data the way a peer speaking this connection's
+// negotiated protocol version expects.
+func (r *namespaceRegistry) Decode(data string) (*protocol.Message, error) {
+	return protocol.DecodeVersion(data, r.version)
+}
+
+// Encode encodes msg the way a peer speaking this connection's
+// negotiated protocol version expects.
+func (r *namespaceRegistry) Encode(msg *protocol.Message) (string, error) {
+	return protocol.EncodeVersion(msg, r.version)
+}
+
+// Of returns the Namespace identified by name, creating it on first
+// use. Emit/BroadcastTo/EmitWithAck issued through it prepend name on
+// the wire, except for DefaultNamespace, which is never written.
+func (r *namespaceRegistry) Of(name string) *Namespace {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.namespaces == nil {
+		r.namespaces = make(map[string]*Namespace)
+	}
+
+	ns, ok := r.namespaces[name]
+	if !ok {
+		ns = newNamespace(name)
+		r.namespaces[name] = ns
+	}
+
+	return ns
+}
+
+// route dispatches a decoded message to the namespace it targets,
+// defaulting to DefaultNamespace when the wire message carried none.
+// AckResponse/BinaryAck messages are not dispatched to a handler;
+// instead they resolve the matching pending ack on channel, if it
+// supports Emit-with-ack. A BinaryEvent/BinaryAck message whose
+// attachments haven't all arrived yet is buffered on channel instead
+// of being routed; HandleAttachment completes it as the remaining
+// binary frames come in.
+func (r *namespaceRegistry) route(channel Channel, msg *protocol.Message) error {
+	if msg.IsBinary() && !msg.Ready() {
+		r.mu.Lock()
+		if r.pending == nil {
+			r.pending = make(map[Channel]*protocol.Message)
+		}
+		r.pending[channel] = msg
+		r.mu.Unlock()
+		return nil
+	}
+
+	return r.dispatch(channel, msg)
+}
+
+// HandleAttachment appends data, a binary websocket frame received
+// after a BinaryEvent/BinaryAck text frame, to the Message on channel
+// that route buffered while waiting on attachments. Once every
+// attachment declared by that message's NumAttachments has arrived,
+// the completed message is routed exactly as route routes one that
+// needed no buffering. Transports call this for each binary frame
+// that follows a binary text frame on the wire.
+func (r *namespaceRegistry) HandleAttachment(channel Channel, data []byte) error {
+	r.mu.Lock()
+	msg, ok := r.pending[channel]
+	r.mu.Unlock()
+
+	if !ok {
+		return ErrNoPendingAttachments
+	}
+
+	msg.AddAttachment(data)
+	if !msg.Ready() {
+		return nil
+	}
+
+	r.mu.Lock()
+	delete(r.pending, channel)
+	r.mu.Unlock()
+
+	return r.dispatch(channel, msg)
+}
+
+// dispatch routes a complete message: AckResponse/BinaryAck messages
+// resolve the matching pending ack on channel if it supports
+// Emit-with-ack, everything else goes to the handler set registered
+// for msg.Namespace.
+func (r *namespaceRegistry) dispatch(channel Channel, msg *protocol.Message) error {
+	if msg.Type == protocol.MessageTypeAckResponse || msg.Type == protocol.MessageTypeBinaryAck {
+		if acker, ok := channel.(AckChannel); ok {
+			acker.Acks().Resolve(msg.AckId, []byte(msg.Args))
+		}
+		return nil
+	}
+
+	name := msg.Namespace
+	if name == "" {
+		name = DefaultNamespace
+	}
+
+	return r.Of(name).Dispatch(channel, msg)
+}
@@ -0,0 +1,55 @@
+package gosocketio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gokuai/golang-socketio/protocol"
+)
+
+type ackRecordingChannel struct {
+	recordingChannel
+	acks *AckWaiter
+}
+
+func (c *ackRecordingChannel) Acks() *AckWaiter { return c.acks }
+
+func TestRegistryRouteDefaultsToDefaultNamespace(t *testing.T) {
+	r := &namespaceRegistry{version: protocol.DefaultProtocolVersion}
+	channel := &recordingChannel{}
+
+	called := false
+	r.Of(DefaultNamespace).On("foo", func(Channel, *protocol.Message) error {
+		called = true
+		return nil
+	})
+
+	msg := &protocol.Message{Type: protocol.MessageTypeEmit, Method: "foo"}
+	if err := r.route(channel, msg); err != nil {
+		t.Fatalf("route: %v", err)
+	}
+	if !called {
+		t.Fatalf("handler on default namespace was not called")
+	}
+}
+
+func TestRegistryRouteResolvesAck(t *testing.T) {
+	r := &namespaceRegistry{version: protocol.DefaultProtocolVersion}
+	channel := &ackRecordingChannel{acks: NewAckWaiter()}
+	id := channel.acks.register()
+	pending := channel.acks.pending[id]
+
+	msg := &protocol.Message{Type: protocol.MessageTypeAckResponse, AckId: id, Args: `1`}
+	if err := r.route(channel, msg); err != nil {
+		t.Fatalf("route: %v", err)
+	}
+
+	select {
+	case data := <-pending:
+		if string(data) != "1" {
+			t.Fatalf("data = %q, want %q", data, "1")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for route to resolve the ack")
+	}
+}
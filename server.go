@@ -0,0 +1,63 @@
+package gosocketio
+
+import "github.com/gokuai/golang-socketio/protocol"
+
+/**
+Server is the entry point for namespace-scoped handler registration on
+the accepting side of a connection. It keeps one Namespace per
+socket.io namespace and routes decoded messages to the namespace they
+target.
+*/
+type Server struct {
+	namespaceRegistry
+}
+
+// NewServer creates a Server speaking protocol.DefaultProtocolVersion,
+// with no namespaces registered; the default namespace is created
+// lazily on first Of(DefaultNamespace) or on first routed message.
+func NewServer() *Server {
+	return &Server{namespaceRegistry{version: protocol.DefaultProtocolVersion}}
+}
+
+// NewServerForHandshake creates a Server whose protocol version is
+// negotiated from the `EIO` querystring parameter of the handshake
+// request, so peers using the legacy v2 framing and modern v3/v4 peers
+// are both served correctly.
+func NewServerForHandshake(rawQuery string) *Server {
+	return &Server{namespaceRegistry{version: protocol.NegotiateVersion(rawQuery)}}
+}
+
+// Of returns the Namespace identified by name, creating it on first
+// use.
+func (s *Server) Of(name string) *Namespace {
+	return s.namespaceRegistry.Of(name)
+}
+
+// OnMessage routes a decoded Message from channel to the namespace it
+// targets. Transports call this once they have a *Message off the
+// wire.
+func (s *Server) OnMessage(channel Channel, msg *protocol.Message) error {
+	return s.route(channel, msg)
+}
+
+// HandleFrame decodes a raw wire frame using this Server's negotiated
+// protocol version and routes the result to the namespace it targets.
+// Transports call this with the text frame read straight off the
+// connection.
+func (s *Server) HandleFrame(channel Channel, data string) error {
+	msg, err := s.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	return s.route(channel, msg)
+}
+
+// HandleAttachment feeds a binary websocket frame that follows a
+// BinaryEvent/BinaryAck text frame into the Message channel is still
+// waiting on attachments for. Transports call this for every binary
+// frame read off the connection until the event it belongs to has been
+// routed.
+func (s *Server) HandleAttachment(channel Channel, data []byte) error {
+	return s.namespaceRegistry.HandleAttachment(channel, data)
+}
@@ -0,0 +1,40 @@
+package gosocketio
+
+import (
+	"testing"
+
+	"github.com/gokuai/golang-socketio/protocol"
+)
+
+func TestNewServerForHandshakeNegotiatesVersion(t *testing.T) {
+	s := NewServerForHandshake("EIO=3")
+	if s.version != protocol.ProtocolVersion3 {
+		t.Fatalf("version = %v, want %v", s.version, protocol.ProtocolVersion3)
+	}
+
+	s = NewServerForHandshake("")
+	if s.version != protocol.ProtocolVersion2 {
+		t.Fatalf("version = %v, want %v", s.version, protocol.ProtocolVersion2)
+	}
+}
+
+func TestServerHandleFrameDropsAuthPayloadForV2Peer(t *testing.T) {
+	s := NewServerForHandshake("EIO=2")
+	channel := &recordingChannel{}
+
+	var received *protocol.Message
+	s.Of(DefaultNamespace).On("", func(c Channel, msg *protocol.Message) error {
+		received = msg
+		return nil
+	})
+
+	if err := s.HandleFrame(channel, `40{"token":"abc"}`); err != nil {
+		t.Fatalf("HandleFrame: %v", err)
+	}
+	if received == nil {
+		t.Fatalf("handler was not dispatched")
+	}
+	if received.Args != "" {
+		t.Fatalf("Args = %q, want empty for a v2 peer", received.Args)
+	}
+}